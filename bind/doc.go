@@ -0,0 +1,29 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package bind emits the Go and C/JNI/ObjC stubs that back gomobile's
+// generated bindings.
+//
+// Its two class-import generators are not symmetric, and that asymmetry is
+// easy to miss from any single entry point, so it's recorded here as well:
+//
+//   - ClassGen is the real reverse-binding generator: it reads a *java.Class
+//     model and drives `import "Java/classpath/to/Class"`, including
+//     subclassing from Go. goTypeNullable lets it shape signatures around
+//     Kotlin nullability once a *java.Class already carries it (suspend
+//     functions are skipped, not bound), but there is no importer here
+//     that reads kotlinp/kotlinx-metadata-jvm output and builds that model
+//     in the first place - nothing can bind a Kotlin class through ClassGen
+//     yet.
+//   - ObjcWrapper only covers the forward-binding direction (an ObjC type
+//     Go already wraps, called from Go). Its goClsMap/Unwrap/Cast/TryCast
+//     support let a Go subclass hand itself back out and let callers narrow
+//     a proxy to a concrete wrapped type, but there is no ClassGen
+//     equivalent for ObjC: no class model read from ObjC/clang metadata and
+//     no `import "ObjC/classpath/to/Class"` path for a class Go wasn't
+//     already wrapping.
+//
+// See the doc comments on ClassGen, ObjcWrapper, and genIsInstance for the
+// detail behind each of those gaps.
+package bind
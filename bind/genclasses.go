@@ -24,6 +24,16 @@ type (
 	// import "Java/classpath/to/Class"
 	//
 	// will work.
+	//
+	// goTypeNullable lets ClassGen shape its Go signatures around nullable
+	// types a Kotlin-compiled class carries, once the caller's *java.Class
+	// model is already populated with that information. Suspend functions
+	// are not bound: isFuncSetSupported skips every FuncSet marked Suspend,
+	// so genFuncDecl's ctx/error signature for them is never emitted. This is not a
+	// Kotlin importer: there is no package here that reads kotlinp/
+	// kotlinx-metadata-jvm output and builds that model in the first place,
+	// so nothing can actually bind a Kotlin class through this generator
+	// yet - that importer is still unbuilt.
 	ClassGen struct {
 		*Printer
 		// JavaPkg is the Java package prefix for the generated classes. The prefix is prepended to the Go
@@ -45,20 +55,56 @@ type (
 		// goClsImports is the list of imports of user packages that contains the Go types implementing Java
 		// classes.
 		goClsImports []string
+		// goClsSuperCount counts, per Go type name, how many Java classes it
+		// embeds. A count greater than one means the type embeds several
+		// Java/... classes/interfaces, so each gets its own Super<ClassName>
+		// accessor instead of a single bare Super.
+		goClsSuperCount map[string]int
 	}
 )
 
 func (g *ClassGen) isSupported(t *java.Type) bool {
 	switch t.Kind {
 	case java.Array:
-		// TODO: Support all array types
-		return t.Elem.Kind == java.Byte
+		// TODO: Support multi-dimensional arrays.
+		return t.Elem.Kind != java.Array && g.isSupported(t.Elem)
 	default:
 		return true
 	}
 }
 
+// primArrayKind returns the lower-case JNI element name for a primitive
+// array type, e.g. "int" for int[], "boolean" for boolean[]. It is used to
+// name the go_seq_*array bridge functions and their n<kind>slice/j<kind>Array
+// C types, which follow the same naming convention as go_seq_*_bytearray.
+func primArrayKind(t *java.Type) string {
+	switch t.Kind {
+	case java.Int:
+		return "int"
+	case java.Short:
+		return "short"
+	case java.Char:
+		return "char"
+	case java.Long:
+		return "long"
+	case java.Float:
+		return "float"
+	case java.Double:
+		return "double"
+	case java.Boolean:
+		return "boolean"
+	default:
+		panic("not a primitive array element kind")
+	}
+}
+
 func (g *ClassGen) isFuncSetSupported(fs *java.FuncSet) bool {
+	// TODO: suspend functions need a Continuation proxy that resumes the
+	// JVM coroutine via a refnum callback into a Go channel. Until that
+	// runtime piece exists, don't generate a body genFuncBody can't back.
+	if fs.Suspend {
+		return false
+	}
 	for _, f := range fs.Funcs {
 		if g.isFuncSupported(f) {
 			return true
@@ -67,6 +113,29 @@ func (g *ClassGen) isFuncSetSupported(fs *java.FuncSet) bool {
 	return false
 }
 
+// hasEmittedSuspend reports whether any class actually emits a suspend
+// FuncSet's "ctx context.Context" signature (see genFuncDecl), so GenGo
+// knows whether the generated file's import list needs "context". Every
+// genFuncDecl call site is gated on isFuncSetSupported first, and that
+// always rejects fs.Suspend, so this is always false until suspend
+// functions grow real support - kept as its own check, rather than
+// assumed, so the import list stays correct the moment that changes.
+func (g *ClassGen) hasEmittedSuspend() bool {
+	for _, cls := range g.classes {
+		for _, fs := range cls.Funcs {
+			if fs.Suspend && g.isFuncSetSupported(fs) {
+				return true
+			}
+		}
+		for _, fs := range cls.AllMethods {
+			if fs.Suspend && g.isFuncSetSupported(fs) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (g *ClassGen) isFuncSupported(f *java.Func) bool {
 	for _, a := range f.Params {
 		if !g.isSupported(a) {
@@ -112,24 +181,151 @@ func (g *ClassGen) goType(t *java.Type, local bool) string {
 			name = "Java." + name
 		}
 		return name
+	case java.TypeVar:
+		// Type parameters are referred to by their bare name in both the
+		// Java package itself and in packages that import it.
+		return t.TypeVar
 	default:
 		panic("invalid kind")
 	}
 }
 
+// goTypeNullable is like goType, but additionally accounts for Kotlin
+// platform nullability: a nullable primitive (Kotlin's Int? and friends)
+// has no natural Go zero value to signal "absent", so it is wrapped in a
+// pointer. Nullable objects and arrays need no wrapping since their Go
+// representation (an interface or a slice) is already nil-able. A nullable
+// String is not wrapped either and stays a plain Go string.
+func (g *ClassGen) goTypeNullable(t *java.Type, local bool) string {
+	base := g.goType(t, local)
+	if t == nil || !t.Nullable {
+		return base
+	}
+	switch t.Kind {
+	case java.Int, java.Boolean, java.Short, java.Char, java.Byte, java.Long, java.Float, java.Double:
+		return "*" + base
+	default:
+		return base
+	}
+}
+
+// typeParamList returns the Go type parameter list for cls, e.g. "[T any]",
+// or "" if cls isn't generic. TypeParams is populated by the javap Signature
+// parser in the java importer.
+func typeParamList(cls *java.Class) string {
+	if len(cls.TypeParams) == 0 {
+		return ""
+	}
+	parts := make([]string, len(cls.TypeParams))
+	for i, p := range cls.TypeParams {
+		parts[i] = p + " any"
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// typeParamArgs returns the Go type argument list for cls, e.g. "[T]", for
+// instantiating cls's own type parameters, or "" if cls isn't generic.
+func typeParamArgs(cls *java.Class) string {
+	if len(cls.TypeParams) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(cls.TypeParams, ", ") + "]"
+}
+
+// anyTypeArgs returns the type argument list instantiating every one of
+// cls's type parameters with interface{}, used for the backwards-compatible
+// <Name>Any alias.
+func anyTypeArgs(cls *java.Class) string {
+	if len(cls.TypeParams) == 0 {
+		return ""
+	}
+	args := make([]string, len(cls.TypeParams))
+	for i := range cls.TypeParams {
+		args[i] = "interface{}"
+	}
+	return "[" + strings.Join(args, ", ") + "]"
+}
+
 // Init initializes the class wrapper generator. Classes is the
 // list of classes to wrap, goClasses is the list of Java classes
 // implemented in Go.
 func (g *ClassGen) Init(classes []*java.Class, goClasses []importers.Struct) {
 	g.goClsMap = make(map[string]string)
+	g.goClsSuperCount = make(map[string]int)
 	impMap := make(map[string]struct{})
+	// byName looks an imported class's AllMethods back up by its Java name,
+	// so the loop below can check two of a Go type's Supers for colliding
+	// method signatures. Only classes that were themselves imported (and so
+	// have their FuncSets populated) can be checked this way; a super that
+	// wasn't separately imported is skipped; see the collision check below.
+	byName := make(map[string]*java.Class, len(classes))
+	for _, cls := range classes {
+		byName[cls.Name] = cls
+	}
 	for _, s := range goClasses {
 		n := s.Pkg + "." + s.Name
-		jn := n
-		if g.JavaPkg != "" {
-			jn = g.JavaPkg + "." + jn
+		// Supers lists every Java class/interface this Go type embeds and
+		// overrides, e.g. a struct embedding both View.OnClickListener and
+		// BroadcastReceiver has two entries here. Structs importing a single
+		// class populate it with one entry; the Pkg/Name-derived name below
+		// is kept only as a fallback for callers that still leave it empty.
+		supers := s.Supers
+		if len(supers) == 0 {
+			jn := n
+			if g.JavaPkg != "" {
+				jn = g.JavaPkg + "." + jn
+			}
+			supers = []string{jn}
 		}
-		g.goClsMap[jn] = n
+		// A Go type can't embed the same Java class/interface twice: there
+		// would be nothing to disambiguate between (superAccessor would
+		// hand out the same Super<Name> accessor name for both), so this is
+		// always an authoring mistake rather than a real ambiguity to
+		// resolve. Catch it at bind time instead of silently collapsing the
+		// two entries into one, which is what the map assignment below
+		// would otherwise do.
+		seen := make(map[string]bool, len(supers))
+		for _, jn := range supers {
+			if seen[jn] {
+				panic(fmt.Sprintf("%s embeds %s more than once", n, jn))
+			}
+			seen[jn] = true
+		}
+		// Two *different* supers can still collide: if both declare the
+		// same JNI signature, a generated csuper_ method dispatching
+		// "super.foo()" has no way to tell which parent's implementation it
+		// means. Catch that here for whichever supers were themselves
+		// imported (their method sets are the only ones known at this
+		// point); a super that wasn't separately imported can't be checked
+		// this way and is silently skipped.
+		//
+		// This can't tell whether the Go type's own method set already
+		// overrides the colliding signature (goClasses carries no method
+		// list, only Supers), so it errors unconditionally rather than
+		// risk silently picking the wrong parent. An explicit override on
+		// the Go side doesn't currently suppress this panic.
+		if len(supers) > 1 {
+			owners := make(map[string]string)
+			for _, jn := range supers {
+				cls, ok := byName[jn]
+				if !ok {
+					continue
+				}
+				for _, fs := range cls.AllMethods {
+					for _, f := range fs.Funcs {
+						sig := f.Name + f.Desc
+						if owner, collide := owners[sig]; collide && owner != jn {
+							panic(fmt.Sprintf("%s embeds both %s and %s, which both declare %s%s; split the colliding method out or stop embedding one of them", n, owner, jn, f.Name, f.Desc))
+						}
+						owners[sig] = jn
+					}
+				}
+			}
+		}
+		for _, jn := range supers {
+			g.goClsMap[jn] = n
+		}
+		g.goClsSuperCount[n] += len(supers)
 		if _, exists := impMap[s.PkgPath]; !exists {
 			impMap[s.PkgPath] = struct{}{}
 			g.goClsImports = append(g.goClsImports, s.PkgPath)
@@ -169,7 +365,7 @@ func (g *ClassGen) GenPackage(idx int) {
 	g.Printf("import \"Java\"\n\n")
 	g.Printf("const _ = Java.Dummy\n\n")
 	for _, cls := range g.typePkgs[jpkg] {
-		g.Printf("type %s Java.%s\n", cls.PkgName, goClsName(cls.Name))
+		g.Printf("type %s%s Java.%s%s\n", cls.PkgName, typeParamList(cls), goClsName(cls.Name), typeParamArgs(cls))
 	}
 	if cls, ok := g.clsPkgs[jpkg]; ok {
 		g.Printf("const (\n")
@@ -188,6 +384,9 @@ func (g *ClassGen) GenPackage(idx int) {
 		// Functions
 	loop:
 		for _, fs := range cls.Funcs {
+			if !g.isFuncSetSupported(fs) {
+				continue
+			}
 			for _, f := range fs.Funcs {
 				if f.Public && g.isFuncSupported(f) {
 					g.Printf("%s func", fs.GoName)
@@ -200,7 +399,11 @@ func (g *ClassGen) GenPackage(idx int) {
 		g.Printf("// Cast takes a proxy for a Java object and converts it to a %s proxy.\n", cls.Name)
 		g.Printf("// Cast panics if the argument is not a proxy or if the underlying object does\n")
 		g.Printf("// not extend or implement %s.\n", cls.Name)
-		g.Printf("Cast func(v interface{}) Java.%s\n", goClsName(cls.Name))
+		g.Printf("Cast func(v interface{}) Java.%s%s\n", goClsName(cls.Name), anyTypeArgs(cls))
+		g.Printf("// TryCast is like Cast, but reports false instead of panicking when v\n")
+		g.Printf("// is not a proxy or the underlying object does not extend or implement\n")
+		g.Printf("// %s.\n", cls.Name)
+		g.Printf("TryCast func(v interface{}) (Java.%s%s, bool)\n", goClsName(cls.Name), anyTypeArgs(cls))
 		g.Outdent()
 		g.Printf(")\n\n")
 	}
@@ -220,6 +423,10 @@ func (g *ClassGen) GenGo() {
 		g.Printf("import \"reflect\"\n\n")
 		g.Printf("import \"fmt\"\n\n")
 	}
+	if g.hasEmittedSuspend() {
+		g.Printf("import \"context\"\n\n")
+	}
+	g.Printf("import \"strings\"\n\n")
 	g.Printf("type proxy interface { Bind_proxy_refnum__() int32 }\n\n")
 	g.Printf("// Suppress unused package error\n\n")
 	g.Printf("var _ = _seq.FromRefNum\n")
@@ -231,6 +438,61 @@ func (g *ClassGen) GenGo() {
 	for _, cls := range g.classes {
 		g.Printf("init_%s()\n", cls.JNIName)
 	}
+	g.Printf("Java.TypeOf = typeOf\n")
+	g.Outdent()
+	g.Printf("}\n\n")
+	g.Printf("// typeOf backs Java.TypeOf. It returns the JNI class name of v's\n")
+	g.Printf("// underlying Java object, followed by the name of every interface it\n")
+	g.Printf("// implements. The GetObjectClass/getInterfaces lookup is cached per\n")
+	g.Printf("// jclass on the C side.\n")
+	g.Printf("//\n")
+	g.Printf("// Note this list does not include ancestor classes: matching a caught\n")
+	g.Printf("// exception against an imported ancestor type only works if the\n")
+	g.Printf("// concrete thrown class was itself imported (its Go proxy then embeds\n")
+	g.Printf("// the ancestor's interface, see genInterface). A thrown class that was\n")
+	g.Printf("// never imported falls back to proxy_error even if one of its Java\n")
+	g.Printf("// ancestors was imported; that would need go_seq_typeof to walk\n")
+	g.Printf("// GetSuperclass chains on the native side, which is out of reach for\n")
+	g.Printf("// this generator alone.\n")
+	g.Printf("func typeOf(v interface{}) []string {\n")
+	g.Indent()
+	g.Printf("p, ok := v.(proxy)\n")
+	g.Printf("if !ok {\n")
+	g.Printf("	return nil\n")
+	g.Printf("}\n")
+	g.Printf("names := C.go_seq_typeof(C.jint(p.Bind_proxy_refnum__()))\n")
+	g.Printf("return strings.Split(string(toSlice(names, true)), \"\\x00\")\n")
+	g.Outdent()
+	g.Printf("}\n\n")
+	g.Printf("// exceptionProxies maps the JNI name of every imported Throwable class\n")
+	g.Printf("// to a constructor for its generated proxy, so a caught exception comes\n")
+	g.Printf("// back as its concrete type (e.g. javalang.IOException) instead of the\n")
+	g.Printf("// generic proxy_error, and callers can use errors.As/CatchAs on it.\n")
+	g.Printf("var exceptionProxies = map[string]func(*_seq.Ref) error{}\n\n")
+	g.Printf("// decodeException turns the refnum of a caught JNI exception (or 0 for\n")
+	g.Printf("// no exception) into a Go error, preferring the most specific imported\n")
+	g.Printf("// Throwable proxy over the generic proxy_error fallback.\n")
+	g.Printf("//\n")
+	g.Printf("// \"Most specific\" is bounded by what was imported: names only lists\n")
+	g.Printf("// the thrown object's own class and the interfaces it implements, so\n")
+	g.Printf("// an exception class that wasn't imported always falls back to\n")
+	g.Printf("// proxy_error, even if one of its unlisted ancestors was imported.\n")
+	g.Printf("func decodeException(ref int32) error {\n")
+	g.Indent()
+	g.Printf("r := _seq.FromRefNum(ref)\n")
+	g.Printf("if r == nil {\n")
+	g.Printf("	return nil\n")
+	g.Printf("}\n")
+	g.Printf("if ref < 0 { // go object\n")
+	g.Printf("	return r.Get().(error)\n")
+	g.Printf("}\n")
+	g.Printf("names := strings.Split(string(toSlice(C.go_seq_typeof(C.jint(ref)), true)), \"\\x00\")\n")
+	g.Printf("for _, n := range names {\n")
+	g.Printf("	if ctor, ok := exceptionProxies[n]; ok {\n")
+	g.Printf("		return ctor(r)\n")
+	g.Printf("	}\n")
+	g.Printf("}\n")
+	g.Printf("return (*proxy_error)(r)\n")
 	g.Outdent()
 	g.Printf("}\n\n")
 	for _, cls := range g.classes {
@@ -422,6 +684,12 @@ func initialUpper(s string) string {
 
 func (g *ClassGen) genFuncDecl(local bool, fs *java.FuncSet) {
 	g.Printf("(")
+	if fs.Suspend {
+		g.Printf("ctx context.Context")
+		if len(fs.Params) > 0 {
+			g.Printf(", ")
+		}
+	}
 	for i, a := range fs.Params {
 		if i > 0 {
 			g.Printf(", ")
@@ -430,17 +698,27 @@ func (g *ClassGen) genFuncDecl(local bool, fs *java.FuncSet) {
 		if i == len(fs.Params)-1 && fs.Variadic {
 			g.Printf("...")
 		}
-		g.Printf(g.goType(a, local))
+		g.Printf(g.goTypeNullable(a, local))
 	}
 	g.Printf(")")
-	if fs.Throws {
+	if fs.Suspend {
+		// A suspend function can always fail through coroutine
+		// cancellation, so its Go signature always returns an error,
+		// regardless of whether the Kotlin declaration lists checked
+		// exceptions.
+		if fs.HasRet {
+			g.Printf(" (%s, error)", g.goTypeNullable(fs.Ret, local))
+		} else {
+			g.Printf(" error")
+		}
+	} else if fs.Throws {
 		if fs.HasRet {
-			g.Printf(" (%s, error)", g.goType(fs.Ret, local))
+			g.Printf(" (%s, error)", g.goTypeNullable(fs.Ret, local))
 		} else {
 			g.Printf(" error")
 		}
 	} else if fs.HasRet {
-		g.Printf(" %s", g.goType(fs.Ret, local))
+		g.Printf(" %s", g.goTypeNullable(fs.Ret, local))
 	}
 }
 
@@ -544,7 +822,13 @@ func (g *ClassGen) genGo(cls *java.Class) {
 	g.Printf("	return\n")
 	g.Printf("}\n")
 	g.Printf("class_%s = clazz\n", cls.JNIName)
+	if cls.Throwable {
+		g.Printf("exceptionProxies[%q] = func(r *_seq.Ref) error { return (*proxy_class_%s%s)(r) }\n", strings.Replace(cls.FindName, ".", "/", -1), cls.JNIName, anyTypeArgs(cls))
+	}
 	for _, fs := range cls.Funcs {
+		if !g.isFuncSetSupported(fs) {
+			continue
+		}
 		var supported bool
 		for _, f := range fs.Funcs {
 			if f.Public && g.isFuncSupported(f) {
@@ -559,10 +843,14 @@ func (g *ClassGen) genGo(cls *java.Class) {
 		g.genFuncDecl(false, fs)
 		g.genFuncBody(cls, fs, "cproxy_s", true)
 	}
-	g.Printf("%s.Cast = func(v interface{}) Java.%s {\n", cls.PkgName, goClsName(cls.Name))
+	// Cast/TryCast can't recover the static type argument a caller imported
+	// cls with, since the proxy they're given is just an interface{}, so
+	// they instantiate cls's type parameters (if any) with interface{} -
+	// the same erased instantiation as the <Name>Any alias.
+	g.Printf("%s.Cast = func(v interface{}) Java.%s%s {\n", cls.PkgName, goClsName(cls.Name), anyTypeArgs(cls))
 	g.Indent()
-	g.Printf("t := reflect.TypeOf((*proxy_class_%s)(nil))\n", cls.JNIName)
-	g.Printf("cv := reflect.ValueOf(v).Convert(t).Interface().(*proxy_class_%s)\n", cls.JNIName)
+	g.Printf("t := reflect.TypeOf((*proxy_class_%s%s)(nil))\n", cls.JNIName, anyTypeArgs(cls))
+	g.Printf("cv := reflect.ValueOf(v).Convert(t).Interface().(*proxy_class_%s%s)\n", cls.JNIName, anyTypeArgs(cls))
 	g.Printf("ref := C.jint(_seq.ToRefNum(cv))\n")
 	g.Printf("if C.go_seq_isinstanceof(ref, class_%s) != 1 {\n", cls.JNIName)
 	g.Printf("	panic(fmt.Errorf(\"%%T is not an instance of %%s\", v, %q))\n", cls.Name)
@@ -570,10 +858,34 @@ func (g *ClassGen) genGo(cls *java.Class) {
 	g.Printf("return cv\n")
 	g.Outdent()
 	g.Printf("}\n")
+	g.Printf("%s.TryCast = func(v interface{}) (Java.%s%s, bool) {\n", cls.PkgName, goClsName(cls.Name), anyTypeArgs(cls))
+	g.Indent()
+	g.Printf("if _, isProxy := v.(proxy); !isProxy {\n")
+	g.Printf("	return nil, false\n")
+	g.Printf("}\n")
+	g.Printf("t := reflect.TypeOf((*proxy_class_%s%s)(nil))\n", cls.JNIName, anyTypeArgs(cls))
+	g.Printf("cv := reflect.ValueOf(v).Convert(t).Interface().(*proxy_class_%s%s)\n", cls.JNIName, anyTypeArgs(cls))
+	g.Printf("ref := C.jint(_seq.ToRefNum(cv))\n")
+	g.Printf("if C.go_seq_isinstanceof(ref, class_%s) != 1 {\n", cls.JNIName)
+	g.Printf("	return nil, false\n")
+	g.Printf("}\n")
+	g.Printf("return cv, true\n")
+	g.Outdent()
+	g.Printf("}\n")
+	g.Printf("Java.CastFuncs[reflect.TypeOf((*Java.%s%s)(nil)).Elem()] = func(v interface{}) (interface{}, bool) {\n", goClsName(cls.Name), anyTypeArgs(cls))
+	g.Indent()
+	g.Printf("return %s.TryCast(v)\n", cls.PkgName)
+	g.Outdent()
+	g.Printf("}\n")
 	g.Outdent()
 	g.Printf("}\n\n")
-	g.Printf("type proxy_class_%s _seq.Ref\n\n", cls.JNIName)
-	g.Printf("func (p *proxy_class_%s) Bind_proxy_refnum__() int32 {\n", cls.JNIName)
+	// proxy_class_X is declared with cls's own type parameters (rather than
+	// erased) so its methods can state a generic param like List.Add's T
+	// precisely, and so the proxy can satisfy Java.X[T] for the T the
+	// caller's Go type actually imported. Cast/TryCast above can't do the
+	// same, since they have no T to instantiate with other than interface{}.
+	g.Printf("type proxy_class_%s%s _seq.Ref\n\n", cls.JNIName, typeParamList(cls))
+	g.Printf("func (p *proxy_class_%s%s) Bind_proxy_refnum__() int32 {\n", cls.JNIName, typeParamArgs(cls))
 	g.Indent()
 	g.Printf("return (*_seq.Ref)(p).Bind_IncNum()\n")
 	g.Outdent()
@@ -582,21 +894,21 @@ func (g *ClassGen) genGo(cls *java.Class) {
 		if !g.isFuncSetSupported(fs) {
 			continue
 		}
-		g.Printf("func (p *proxy_class_%s) %s", cls.JNIName, fs.GoName)
+		g.Printf("func (p *proxy_class_%s%s) %s", cls.JNIName, typeParamArgs(cls), fs.GoName)
 		g.genFuncDecl(false, fs)
 		g.genFuncBody(cls, fs, "cproxy", false)
 	}
 	if cls.Throwable {
-		g.Printf("func (p *proxy_class_%s) Error() string {\n", cls.JNIName)
+		g.Printf("func (p *proxy_class_%s%s) Error() string {\n", cls.JNIName, typeParamArgs(cls))
 		g.Printf("	return p.ToString()\n")
 		g.Printf("}\n")
 	}
 	if goName, ok := g.goClsMap[cls.Name]; ok {
-		g.Printf("func (p *proxy_class_%s) Super() Java.%s {\n", cls.JNIName, goClsName(cls.Name))
-		g.Printf("	return &super_%s{p}\n", cls.JNIName)
+		g.Printf("func (p *proxy_class_%s%s) %s() Java.%s%s {\n", cls.JNIName, typeParamArgs(cls), g.superAccessor(goName, cls.Name), goClsName(cls.Name), typeParamArgs(cls))
+		g.Printf("	return &super_%s%s{p}\n", cls.JNIName, typeParamArgs(cls))
 		g.Printf("}\n\n")
-		g.Printf("type super_%s struct {*proxy_class_%[1]s}\n\n", cls.JNIName)
-		g.Printf("func (p *proxy_class_%s) Unwrap() interface{} {\n", cls.JNIName)
+		g.Printf("type super_%s%s struct {*proxy_class_%s%s}\n\n", cls.JNIName, typeParamList(cls), cls.JNIName, typeParamArgs(cls))
+		g.Printf("func (p *proxy_class_%s%s) Unwrap() interface{} {\n", cls.JNIName, typeParamArgs(cls))
 		g.Indent()
 		g.Printf("goRefnum := C.go_seq_unwrap(C.jint(p.Bind_proxy_refnum__()))\n")
 		g.Printf("return _seq.FromRefNum(int32(goRefnum)).Get().(*%s)\n", goName)
@@ -606,7 +918,7 @@ func (g *ClassGen) genGo(cls *java.Class) {
 			if !g.isFuncSetSupported(fs) {
 				continue
 			}
-			g.Printf("func (p *super_%s) %s", cls.JNIName, fs.GoName)
+			g.Printf("func (p *super_%s%s) %s", cls.JNIName, typeParamArgs(cls), fs.GoName)
 			g.genFuncDecl(false, fs)
 			g.genFuncBody(cls, fs, "csuper", false)
 		}
@@ -719,9 +1031,9 @@ func (g *ClassGen) genFuncBody(cls *java.Class, fs *java.FuncSet, prefix string,
 func (g *ClassGen) genFuncRet(fs *java.FuncSet, f *java.Func, mustReturn bool) {
 	if f.Ret != nil {
 		g.genRead("_res", "res.res", f.Ret, modeRetained)
-		g.genRefRead("_exc", "res.exc", "error", "proxy_error", true)
+		g.Printf("_exc := decodeException(int32(res.exc))\n")
 	} else {
-		g.genRefRead("_exc", "res", "error", "proxy_error", true)
+		g.Printf("_exc := decodeException(int32(res))\n")
 	}
 	if !fs.Throws {
 		g.Printf("if (_exc != nil) { panic(_exc) }\n")
@@ -761,18 +1073,96 @@ func (g *ClassGen) genRead(to, from string, t *java.Type, mode varMode) {
 	case java.String:
 		g.Printf("%s := decodeString(%s)\n", to, from)
 	case java.Array:
-		if t.Elem.Kind != java.Byte {
-			panic("unsupported array type")
+		switch t.Elem.Kind {
+		case java.Byte:
+			g.Printf("%s := toSlice(%s, %v)\n", to, from, mode == modeRetained)
+		case java.String:
+			g.Printf("%s := toStringSlice(%s, %v)\n", to, from, mode == modeRetained)
+		case java.Object:
+			refnums := to + "Refnums"
+			g.Printf("%s := toRefnumSlice(%s, %v)\n", refnums, from, mode == modeRetained)
+			g.Printf("%s := make(%s, len(%s))\n", to, g.goType(t, false), refnums)
+			g.Printf("for __i, __r := range %s {\n", refnums)
+			g.Indent()
+			_, hasProxy := g.imported[t.Elem.Class]
+			g.Printf("__ref := _seq.FromRefNum(__r)\n")
+			g.Printf("if __ref != nil {\n")
+			g.Indent()
+			g.Printf("if __r < 0 { // go object\n")
+			g.Printf("	%s[__i] = __ref.Get().(%s)\n", to, g.goType(t.Elem, false))
+			g.Printf("} else { // foreign object\n")
+			if hasProxy {
+				g.Printf("	%s[__i] = (*%s)(__ref)\n", to, "proxy_class_"+flattenName(t.Elem.Class))
+			} else {
+				g.Printf("	%s[__i] = __ref\n", to)
+			}
+			g.Printf("}\n")
+			g.Outdent()
+			g.Printf("}\n")
+			g.Outdent()
+			g.Printf("}\n")
+		case java.TypeVar:
+			// Same refnum-slice shape as the Object case above, but the
+			// element type is a bare type parameter: assert through
+			// interface{} instead of casting to a known proxy type, same
+			// as genTypeVarRead does for a single value.
+			refnums := to + "Refnums"
+			g.Printf("%s := toRefnumSlice(%s, %v)\n", refnums, from, mode == modeRetained)
+			g.Printf("%s := make(%s, len(%s))\n", to, g.goType(t, false), refnums)
+			g.Printf("for __i, __r := range %s {\n", refnums)
+			g.Indent()
+			g.Printf("__ref := _seq.FromRefNum(__r)\n")
+			g.Printf("if __ref != nil {\n")
+			g.Indent()
+			g.Printf("if __r < 0 { // go object\n")
+			g.Printf("	%s[__i] = __ref.Get().(%s)\n", to, t.Elem.TypeVar)
+			g.Printf("} else { // foreign object\n")
+			g.Printf("	%s[__i] = interface{}(__ref).(%s)\n", to, t.Elem.TypeVar)
+			g.Printf("}\n")
+			g.Outdent()
+			g.Printf("}\n")
+			g.Outdent()
+			g.Printf("}\n")
+		default:
+			name := initialUpper(primArrayKind(t.Elem))
+			g.Printf("%s := to%sSlice(%s, %v)\n", to, name, from, mode == modeRetained)
 		}
-		g.Printf("%s := toSlice(%s, %v)\n", to, from, mode == modeRetained)
 	case java.Object:
+		// TODO: when t is a supertype (e.g. java.lang.Object), consult a
+		// jclass->proxy dispatch table built from g.imported so a value
+		// that is actually, say, an ArrayList comes back as Java.ArrayList
+		// rather than as the statically declared type. For now every read
+		// is typed exactly as declared, same as before TryCast/TypeOf.
 		_, hasProxy := g.imported[t.Class]
 		g.genRefRead(to, from, g.goType(t, false), "proxy_class_"+flattenName(t.Class), hasProxy)
+	case java.TypeVar:
+		g.genTypeVarRead(to, from, t.TypeVar)
 	default:
 		panic("invalid kind")
 	}
 }
 
+// genTypeVarRead reads a refnum into a bare type-parameter-typed variable.
+// It can't reuse genRefRead's Object path: that assigns straight into the
+// declared variable, which works when the declared type is a concrete
+// interface but not when it's a type parameter like T - "v = ref" doesn't
+// type-check against an unconstrained T. Go through interface{} and assert
+// back to T instead, which works for both arms (a type assertion to a type
+// parameter checks against the caller's instantiated type at runtime).
+func (g *ClassGen) genTypeVarRead(to, from, typeVar string) {
+	g.Printf("var %s %s\n", to, typeVar)
+	g.Printf("%s_ref := _seq.FromRefNum(int32(%s))\n", to, from)
+	g.Printf("if %s_ref != nil {\n", to)
+	g.Indent()
+	g.Printf("if %s < 0 { // go object\n", from)
+	g.Printf("	%s = %s_ref.Get().(%s)\n", to, to, typeVar)
+	g.Printf("} else { // foreign object\n")
+	g.Printf("	%s = interface{}(%s_ref).(%s)\n", to, to, typeVar)
+	g.Printf("}\n")
+	g.Outdent()
+	g.Printf("}\n")
+}
+
 func (g *ClassGen) genRefRead(to, from string, intfName, proxyName string, hasProxy bool) {
 	g.Printf("var %s %s\n", to, intfName)
 	g.Printf("%s_ref := _seq.FromRefNum(int32(%s))\n", to, from)
@@ -801,15 +1191,55 @@ func (g *ClassGen) genWrite(dst, v string, t *java.Type, mode varMode) {
 	case java.String:
 		g.Printf("%s := encodeString(%s)\n", dst, v)
 	case java.Array:
-		if t.Elem.Kind != java.Byte {
-			panic("unsupported array type")
+		switch t.Elem.Kind {
+		case java.Byte:
+			g.Printf("%s := fromSlice(%s, %v)\n", dst, v, mode == modeRetained)
+		case java.String:
+			g.Printf("%s := fromStringSlice(%s, %v)\n", dst, v, mode == modeRetained)
+		case java.Object:
+			refnums := dst + "Refnums"
+			g.Printf("%s := make([]int32, len(%s))\n", refnums, v)
+			g.Printf("for __i, __v := range %s {\n", v)
+			g.Indent()
+			g.Printf("if __v != nil {\n")
+			g.Printf("	%s[__i] = _seq.ToRefNum(__v)\n", refnums)
+			g.Printf("}\n")
+			g.Outdent()
+			g.Printf("}\n")
+			g.Printf("%s := fromRefnumSlice(%s, %v)\n", dst, refnums, mode == modeRetained)
+		case java.TypeVar:
+			// Same shape as the Object case above, but each element's
+			// declared type is a bare type parameter, so it can't be
+			// compared to nil directly - box it through interface{} first,
+			// same as genWrite's scalar java.TypeVar case does.
+			refnums := dst + "Refnums"
+			g.Printf("%s := make([]int32, len(%s))\n", refnums, v)
+			g.Printf("for __i, __v := range %s {\n", v)
+			g.Indent()
+			g.Printf("if __iface := interface{}(__v); __iface != nil {\n")
+			g.Printf("	%s[__i] = _seq.ToRefNum(__iface)\n", refnums)
+			g.Printf("}\n")
+			g.Outdent()
+			g.Printf("}\n")
+			g.Printf("%s := fromRefnumSlice(%s, %v)\n", dst, refnums, mode == modeRetained)
+		default:
+			name := initialUpper(primArrayKind(t.Elem))
+			g.Printf("%s := from%sSlice(%s, %v)\n", dst, name, v, mode == modeRetained)
 		}
-		g.Printf("%s := fromSlice(%s, %v)\n", dst, v, mode == modeRetained)
 	case java.Object:
 		g.Printf("var %s C.jint = _seq.NullRefNum\n", dst)
 		g.Printf("if %s != nil {\n", v)
 		g.Printf("	%s = C.jint(_seq.ToRefNum(%s))\n", dst, v)
 		g.Printf("}\n")
+	case java.TypeVar:
+		// v's declared type is a bare type parameter, which - unlike the
+		// Object case above - isn't guaranteed to be an interface, so it
+		// can't be compared to nil directly ("v != nil" doesn't type-check
+		// against an unconstrained T). Box it through interface{} first.
+		g.Printf("var %s C.jint = _seq.NullRefNum\n", dst)
+		g.Printf("if %sIface := interface{}(%s); %sIface != nil {\n", dst, v, dst)
+		g.Printf("	%s = C.jint(_seq.ToRefNum(%sIface))\n", dst, dst)
+		g.Printf("}\n")
 	default:
 		panic("invalid kind")
 	}
@@ -838,11 +1268,18 @@ func (g *ClassGen) genJavaToC(v string, t *java.Type) {
 	case java.String:
 		g.Printf("nstring _%s = go_seq_from_java_string(env, %s);\n", v, v)
 	case java.Array:
-		if t.Elem.Kind != java.Byte {
-			panic("unsupported array type")
+		switch t.Elem.Kind {
+		case java.Byte:
+			g.Printf("nbyteslice _%s = go_seq_from_java_bytearray(env, %s, 1);\n", v, v)
+		case java.String:
+			g.Printf("nbyteslice _%s = go_seq_from_java_stringarray(env, %s, 1);\n", v, v)
+		case java.Object, java.TypeVar:
+			g.Printf("nrefnumslice _%s = go_seq_from_java_objectarray(env, %s, 1);\n", v, v)
+		default:
+			name := primArrayKind(t.Elem)
+			g.Printf("n%sslice _%s = go_seq_from_java_%sarray(env, %s, 1);\n", name, v, name, v)
 		}
-		g.Printf("nbyteslice _%s = go_seq_from_java_bytearray(env, %s, 1);\n", v, v)
-	case java.Object:
+	case java.Object, java.TypeVar:
 		g.Printf("jint _%s = go_seq_to_refnum(env, %s);\n", v, v)
 	default:
 		panic("invalid kind")
@@ -856,11 +1293,18 @@ func (g *ClassGen) genCToJava(v string, t *java.Type) {
 	case java.String:
 		g.Printf("jstring _%s = go_seq_to_java_string(env, %s);\n", v, v)
 	case java.Array:
-		if t.Elem.Kind != java.Byte {
-			panic("unsupported array type")
+		switch t.Elem.Kind {
+		case java.Byte:
+			g.Printf("jbyteArray _%s = go_seq_to_java_bytearray(env, %s, 0);\n", v, v)
+		case java.String:
+			g.Printf("jobjectArray _%s = go_seq_to_java_stringarray(env, %s, 0);\n", v, v)
+		case java.Object, java.TypeVar:
+			g.Printf("jobjectArray _%s = go_seq_to_java_objectarray(env, %s, 0);\n", v, v)
+		default:
+			name := primArrayKind(t.Elem)
+			g.Printf("j%sArray _%s = go_seq_to_java_%sarray(env, %s, 0);\n", name, v, name, v)
 		}
-		g.Printf("jbyteArray _%s = go_seq_to_java_bytearray(env, %s, 0);\n", v, v)
-	case java.Object:
+	case java.Object, java.TypeVar:
 		g.Printf("jobject _%s = go_seq_from_refnum(env, %s, NULL, NULL);\n", v, v)
 	default:
 		panic("invalid kind")
@@ -871,8 +1315,31 @@ func goClsName(n string) string {
 	return initialUpper(strings.Replace(n, ".", "_", -1))
 }
 
+// superAccessor returns the name of the method that exposes the Java
+// superclass/interface clsName of the Go type goName. Go types that embed a
+// single Java type keep the plain Super name; types that embed more than one
+// get one disambiguated accessor per embedded type, named after it, since
+// Super alone would otherwise be emitted more than once on the same
+// interface and proxy struct.
+//
+// Note: this only disambiguates the accessor name. Init rejects the
+// degenerate case of the same Java class listed twice for one Go type, and
+// also panics if two *different* embedded, imported supers declare a
+// colliding JNI signature between them (it can't tell whether the Go type's
+// own method set already overrides that signature, so it can't resolve the
+// ambiguity - only flag it). Widening the JNI proxy class's implements
+// clause to list every embedded interface so super.foo() dispatches against
+// the right one is still out of scope for this generator; that belongs to
+// the Java proxy template.
+func (g *ClassGen) superAccessor(goName, clsName string) string {
+	if g.goClsSuperCount[goName] <= 1 {
+		return "Super"
+	}
+	return "Super" + goClsName(clsName)
+}
+
 func (g *ClassGen) genInterface(cls *java.Class) {
-	g.Printf("type %s interface {\n", goClsName(cls.Name))
+	g.Printf("type %s%s interface {\n", goClsName(cls.Name), typeParamList(cls))
 	g.Indent()
 	// Methods
 	for _, fs := range cls.AllMethods {
@@ -884,7 +1351,7 @@ func (g *ClassGen) genInterface(cls *java.Class) {
 		g.Printf("\n")
 	}
 	if goName, ok := g.goClsMap[cls.Name]; ok {
-		g.Printf("Super() %s\n", goClsName(cls.Name))
+		g.Printf("%s() %s%s\n", g.superAccessor(goName, cls.Name), goClsName(cls.Name), typeParamArgs(cls))
 		g.Printf("// Unwrap returns the Go object this Java instance\n")
 		g.Printf("// is wrapping.\n")
 		g.Printf("// The return value is a %s, but the delclared type is\n", goName)
@@ -892,10 +1359,25 @@ func (g *ClassGen) genInterface(cls *java.Class) {
 		g.Printf("Unwrap() interface{}\n")
 	}
 	if cls.Throwable {
-		g.Printf("Error() string\n")
+		embedsAncestor := false
+		if cls.Extends != nil && cls.Extends.Throwable {
+			_, embedsAncestor = g.imported[cls.Extends.Name]
+		}
+		if embedsAncestor {
+			// Embed the ancestor exception interface so Java's catch-chain
+			// assignability (e.g. IOException is-a Exception) carries over.
+			g.Printf("%s\n", goClsName(cls.Extends.Name))
+		} else {
+			g.Printf("Error() string\n")
+		}
 	}
 	g.Outdent()
 	g.Printf("}\n\n")
+	if len(cls.TypeParams) > 0 {
+		// Preserve a non-generic spelling for callers that don't need
+		// static type safety on the Java generic parameter.
+		g.Printf("type %sAny = %s%s\n\n", goClsName(cls.Name), goClsName(cls.Name), anyTypeArgs(cls))
+	}
 }
 
 // Flatten java class names. "java.package.Class$Inner" is converted to
@@ -908,9 +1390,52 @@ var (
 	classesPkgHeader = gobindPreamble + `
 package Java
 
+import (
+	"errors"
+	"reflect"
+)
+
 // Used to silence this package not used errors
 const Dummy = 0
 
+// CatchAs reports whether err's chain contains an exception of type T,
+// mirroring a single arm of a Java catch chain. It is a thin wrapper
+// around errors.As for the common case of a single target type.
+func CatchAs[T error](err error) (T, bool) {
+	var target T
+	ok := errors.As(err, &target)
+	return target, ok
+}
+
+// TypeOf returns the JNI class name of v's underlying Java object, followed
+// by the name of every interface it implements, or nil if v isn't a proxy
+// for a Java object. It is wired up to the real implementation by
+// initClasses.
+var TypeOf func(v interface{}) []string
+
+// CastFuncs maps the reflect.Type of an imported class's interface to a
+// TryCast-style function over it. It is wired up to every imported class's
+// TryCast by initClasses and backs As; it isn't meant to be used directly.
+var CastFuncs = map[reflect.Type]func(interface{}) (interface{}, bool){}
+
+// As narrows a proxy for a Java object to the imported class T, reporting
+// false if v isn't a proxy, or if the underlying object does not extend or
+// implement T's class. Unlike a package's own Cast/TryCast, As dispatches
+// through CastFuncs, so it also works for a class that isn't imported
+// directly into the calling package.
+func As[T any](v interface{}) (T, bool) {
+	var zero T
+	fn, ok := CastFuncs[reflect.TypeOf(&zero).Elem()]
+	if !ok {
+		return zero, false
+	}
+	r, ok := fn(v)
+	if !ok {
+		return zero, false
+	}
+	return r.(T), true
+}
+
 `
 	classesCHeader = gobindPreamble + `
 #include <jni.h>
@@ -5,14 +5,27 @@
 package bind
 
 import (
+	"fmt"
 	"path"
 	"strings"
 
+	"github.com/baguettex/gomobile/internal/importers"
 	"github.com/baguettex/gomobile/internal/importers/objc"
 )
 
 type (
-	// ObjCWrapper generates Go and C stubs for ObjC interfaces and protocols.
+	// ObjCWrapper generates Go and C stubs for ObjC interfaces and
+	// protocols, for the existing forward-binding direction (an ObjC type
+	// being called from Go). goClsMap/Unwrap and Cast/TryCast let a Go type
+	// that subclasses one of these wrapped ObjC types hand itself back out
+	// through the proxy, and let callers narrow a proxy to a concrete
+	// wrapped type - but this is not a reverse-binding importer: there is
+	// no ObjC equivalent of ClassGen (no class model read from ObjC
+	// metadata, and no
+	// `import "ObjC/classpath/to/Class"` support for binding against an
+	// arbitrary ObjC class Go wasn't already wrapping). That subsystem is
+	// still unbuilt; see this package's doc comment for how it lines up
+	// against ClassGen's own reverse-binding gap on the Java/Kotlin side.
 	ObjcWrapper struct {
 		*Printer
 		imported map[string]*objc.Named
@@ -28,20 +41,52 @@ type (
 		typePkgs map[string]*objc.Named
 		// supers is the map of types that need Super methods.
 		supers map[string]struct{}
+		// structs is the set of distinct objc.Struct types (e.g. CGRect,
+		// NSRange) referenced by any bound method, keyed by struct name.
+		structs map[string]*objc.Type
+		// structOrder is structs in first-seen order, for deterministic output.
+		structOrder []string
+		// blocks is the set of distinct block signatures seen in any bound
+		// method, keyed by the signature string returned by blockSig. Each
+		// gets exactly one C trampoline, shared by every block parameter
+		// with that signature.
+		blocks map[string]*objc.Func
+		// blockOrder is blocks in first-seen order, for deterministic output.
+		blockOrder []string
+		// goClsMap is the map of ObjC type names to Go type names, qualified
+		// with package name. Go types that implement ObjC interfaces need
+		// Super methods and Unwrap methods, mirroring ClassGen.goClsMap.
+		goClsMap map[string]string
+		// goClsImports is the list of imports of user packages that contain
+		// the Go types implementing ObjC interfaces.
+		goClsImports []string
 	}
 )
 
-// Init initializes the ObjC types wrapper generator. Types is the
-// list of types to wrap, genNames the list of generated type names.
-func (g *ObjcWrapper) Init(types []*objc.Named, genNames []string) {
+// Init initializes the ObjC types wrapper generator. Types is the list of
+// types to wrap, genNames the list of generated type names, and goClasses
+// the Go types that implement one of those ObjC interfaces or protocols.
+func (g *ObjcWrapper) Init(types []*objc.Named, genNames []string, goClasses []importers.Struct) {
 	g.supers = make(map[string]struct{})
 	for _, s := range genNames {
 		g.supers[s] = struct{}{}
 	}
+	g.goClsMap = make(map[string]string)
+	impMap := make(map[string]struct{})
+	for _, s := range goClasses {
+		n := s.Pkg + "." + s.Name
+		g.goClsMap[s.Name] = n
+		if _, exists := impMap[s.PkgPath]; !exists {
+			impMap[s.PkgPath] = struct{}{}
+			g.goClsImports = append(g.goClsImports, s.PkgPath)
+		}
+	}
 	g.types = types
 	g.imported = make(map[string]*objc.Named)
 	g.modMap = make(map[string][]*objc.Named)
 	g.typePkgs = make(map[string]*objc.Named)
+	g.structs = make(map[string]*objc.Type)
+	g.blocks = make(map[string]*objc.Func)
 	pkgSet := make(map[string]struct{})
 	for _, n := range types {
 		g.imported[n.GoName] = n
@@ -58,6 +103,52 @@ func (g *ObjcWrapper) Init(types []*objc.Named, genNames []string) {
 			g.pkgNames = append(g.pkgNames, n.Module)
 		}
 		g.pkgNames = append(g.pkgNames, typePkg)
+		for _, f := range n.AllMethods {
+			g.collectStruct(f.Ret)
+			for _, p := range f.Params {
+				g.collectStruct(p.Type)
+				if p.Type.Kind == objc.Block {
+					g.collectBlock(p.Type.Block)
+				}
+			}
+		}
+	}
+}
+
+// collectBlock records f in g.blocks, keyed by its signature, so that
+// every block parameter sharing that signature reuses a single generated
+// C trampoline instead of emitting one per occurrence.
+func (g *ObjcWrapper) collectBlock(f *objc.Func) {
+	sig := blockSig(f)
+	if _, exists := g.blocks[sig]; !exists {
+		g.blocks[sig] = f
+		g.blockOrder = append(g.blockOrder, sig)
+	}
+}
+
+// blockSig derives a stable, identifier-safe name for a block signature
+// from its parameter types, used to dedupe and name cblock_* trampolines.
+func blockSig(f *objc.Func) string {
+	if len(f.Params) == 0 {
+		return "void"
+	}
+	var parts []string
+	for _, p := range f.Params {
+		parts = append(parts, strings.Replace(p.Type.Name, " ", "_", -1))
+	}
+	return strings.Join(parts, "_")
+}
+
+// collectStruct records t in g.structs if it is an objc.Struct, so a single
+// mirrored Go struct definition can be emitted for it regardless of how
+// many methods reference it.
+func (g *ObjcWrapper) collectStruct(t *objc.Type) {
+	if t == nil || t.Kind != objc.Struct {
+		return
+	}
+	if _, exists := g.structs[t.Name]; !exists {
+		g.structs[t.Name] = t
+		g.structOrder = append(g.structOrder, t.Name)
 	}
 }
 
@@ -65,10 +156,31 @@ func (g *ObjcWrapper) GenM() {
 	g.Printf(gobindPreamble)
 	// For objc_msgSend* functions.
 	g.Printf("@import ObjectiveC.message;\n")
+	g.Printf("#include <Block.h>\n")
 	g.Printf("#include \"seq.h\"\n")
 	g.Printf("#include \"interfaces.h\"\n\n")
+	g.Printf(nserrorSnapshot)
+	g.Printf(msgSendStretDispatch)
+	g.Printf(kvoObserverClass)
+	for _, n := range g.types {
+		for _, p := range n.Properties {
+			if !g.isSupported(p.Type) {
+				continue
+			}
+			g.genKVOFuncs(n, p)
+		}
+	}
+	for _, sig := range g.blockOrder {
+		g.Printf("extern void cblock_%s(int refnum", sig)
+		for i, p := range g.blocks[sig].Params {
+			g.Printf(", %s a%d", g.cType(p.Type), i)
+		}
+		g.Printf(");\n")
+	}
+	g.Printf("\n")
 	for _, n := range g.types {
 		g.genM(n)
+		g.genIsInstance(n)
 	}
 	g.Printf("\n")
 	for _, n := range g.types {
@@ -93,12 +205,16 @@ func (g *ObjcWrapper) genCFuncBody(n *objc.Named, f *objc.Func, super bool) {
 		g.Printf("%s _this = go_seq_from_refnum(this).obj;\n", n.ObjcType())
 	}
 	var errParam *objc.Param
+	var blockParams []*objc.Param
 	for i, a := range f.Params {
 		if i == len(f.Params)-1 && g.isErrorType(a.Type) {
 			errParam = a
 			break
 		}
 		g.genCToObjC(a.Name, a.Type, modeTransient)
+		if a.Type.Kind == objc.Block {
+			blockParams = append(blockParams, a)
+		}
 	}
 	if errParam != nil {
 		g.Printf("NSError *%s = nil;\n", errParam.Name)
@@ -129,14 +245,7 @@ func (g *ObjcWrapper) genCFuncBody(n *objc.Named, f *objc.Func, super bool) {
 	// a call to super looks like this:
 	//
 	// ret = ((<return type> (*)(id, SEL, <argument_types>))objc_msgSendSuper)(<struct objc_super>, <arguments>)
-	if f.Ret != nil {
-		switch f.Ret.Kind {
-		case objc.String, objc.Bool, objc.Data, objc.Int, objc.Uint, objc.Short, objc.Ushort, objc.Char, objc.Uchar, objc.Float, objc.Double, objc.Class, objc.Protocol:
-		default:
-			// If support for struct results is added, objc_msgSend_stret must be used
-			panic("unsupported type kind - use objc_msgSend_stret?")
-		}
-	}
+	stret := f.Ret != nil && f.Ret.Kind == objc.Struct
 	g.Printf("((%s (*)(", retType)
 	if super {
 		g.Printf("struct objc_super *")
@@ -148,9 +257,14 @@ func (g *ObjcWrapper) genCFuncBody(n *objc.Named, f *objc.Func, super bool) {
 		g.Printf(", %s", g.objcType(a.Type))
 	}
 	g.Printf("))")
-	if super {
+	switch {
+	case super && stret:
+		g.Printf("gomobile_msgSendSuper_stret")
+	case super:
 		g.Printf("objc_msgSendSuper")
-	} else {
+	case stret:
+		g.Printf("gomobile_msgSend_stret")
+	default:
 		g.Printf("objc_msgSend")
 	}
 	g.Printf(")(")
@@ -172,6 +286,14 @@ func (g *ObjcWrapper) genCFuncBody(n *objc.Named, f *objc.Func, super bool) {
 		g.Printf(", %s", arg)
 	}
 	g.Printf(");\n")
+	for _, a := range blockParams {
+		// Transient blocks only need to stay valid for the duration of this
+		// call; release the copy genCToObjC made now that objc_msgSend has
+		// returned, rather than leaking it.
+		g.Printf("if (_%s != nil) {\n", a.Name)
+		g.Printf("	_Block_release(_%s);\n", a.Name)
+		g.Printf("}\n")
+	}
 	if errParam != nil {
 		g.Printf("NSError *_%s = nil;\n", errParam.Name)
 		if f.Ret != nil {
@@ -181,7 +303,7 @@ func (g *ObjcWrapper) genCFuncBody(n *objc.Named, f *objc.Func, super bool) {
 		}
 		g.Printf("	_%[1]s = %[1]s;\n", errParam.Name)
 		g.Printf("}\n")
-		g.genObjCToC("_"+errParam.Name, g.errType(), modeRetained)
+		g.genNSErrorCapture("_" + errParam.Name)
 	}
 	ret := f.Ret
 	if ret != nil && ret.Kind == objc.Bool && errParam != nil {
@@ -204,8 +326,230 @@ func (g *ObjcWrapper) genCFuncBody(n *objc.Named, f *objc.Func, super bool) {
 	g.Printf("}\n\n")
 }
 
-func (_ *ObjcWrapper) errType() *objc.Type {
-	return &objc.Type{Kind: objc.Class, Name: "NSError"}
+// nserrorSnapshot is emitted once per generated .m file. It flattens an
+// NSError's userInfo into a NUL-delimited "key\x00kind\x00value\x00..."
+// byte buffer that decodeNSErrorUserInfo on the Go side parses back into
+// an ObjC.NSError's UserInfo map. kind is "s" for NSString, "n" for
+// NSNumber leaves, and "e" for nested NSError leaves: their domain, code
+// and userInfo (itself recursively snapshotted and base64-encoded, since
+// its own NULs would otherwise be mistaken for field separators) are
+// packed into value as "domain\x1fcode\x1fbase64(userInfo)".
+const nserrorSnapshot = `static NSData *go_seq_nserror_userinfo_snapshot(NSDictionary *userInfo) {
+	NSMutableData *data = [NSMutableData data];
+	for (id key in userInfo) {
+		id val = userInfo[key];
+		NSString *kind;
+		NSString *str;
+		if ([val isKindOfClass:[NSString class]]) {
+			kind = @"s";
+			str = val;
+		} else if ([val isKindOfClass:[NSNumber class]]) {
+			kind = @"n";
+			str = [val stringValue];
+		} else if ([val isKindOfClass:[NSError class]]) {
+			kind = @"e";
+			NSError *nested = (NSError *)val;
+			NSData *nestedInfo = go_seq_nserror_userinfo_snapshot(nested.userInfo);
+			NSString *nestedInfoB64 = [nestedInfo base64EncodedStringWithOptions:0];
+			str = [NSString stringWithFormat:@"%@\x1f%ld\x1f%@", nested.domain, (long)nested.code, nestedInfoB64];
+		} else {
+			continue;
+		}
+		NSString *entry = [NSString stringWithFormat:@"%@\x00%@\x00%@\x00", key, kind, str];
+		[data appendData:[entry dataUsingEncoding:NSUTF8StringEncoding]];
+	}
+	return data;
+}
+
+`
+
+// decodeNSErrorUserInfo parses a NUL-delimited "key\x00kind\x00value\x00..."
+// buffer produced by nserrorSnapshot into a Go map, recursively decoding
+// nested NSError leaves (kind "e") back into *ObjC.NSError values with
+// their own real Domain/Code/UserInfo instead of just a description.
+const decodeNSErrorUserInfoFunc = `func decodeNSErrorUserInfo(buf []byte) map[string]interface{} {
+	info := make(map[string]interface{})
+	parts := strings.Split(string(buf), "\x00")
+	for i := 0; i+2 < len(parts); i += 3 {
+		key, kind, val := parts[i], parts[i+1], parts[i+2]
+		switch kind {
+		case "e":
+			fields := strings.SplitN(val, "\x1f", 3)
+			if len(fields) != 3 {
+				info[key] = val
+				continue
+			}
+			code, _ := strconv.Atoi(fields[1])
+			nestedBuf, _ := base64.StdEncoding.DecodeString(fields[2])
+			info[key] = &ObjC.NSError{
+				Domain:   fields[0],
+				Code:     code,
+				UserInfo: decodeNSErrorUserInfo(nestedBuf),
+			}
+		default:
+			info[key] = val
+		}
+	}
+	return info
+}
+
+`
+
+// decodeNSErrorFunc is emitted once per generated Go file. It turns a
+// C.nserror value produced by nserrorSnapshot back into an *ObjC.NSError.
+const decodeNSErrorFunc = `func decodeNSError(e C.nserror) error {
+	domain := decodeString(e.domain)
+	if domain == "" {
+		return nil
+	}
+	info := decodeNSErrorUserInfo(toSlice(e.userInfo, true))
+	err := &ObjC.NSError{
+		Domain:   domain,
+		Code:     int(e.code),
+		UserInfo: info,
+	}
+	if u, ok := info["NSUnderlyingError"].(error); ok {
+		err.Underlying = u
+	}
+	return err
+}
+
+`
+
+// kvoFireFunc is emitted once per generated Go file. GoSeqKVOObserver calls
+// it via cgo whenever the ObjC KVO machinery fires for a property an
+// Observe<Prop> call registered interest in.
+const kvoFireFunc = `//export cproxy_kvo_fire
+func cproxy_kvo_fire(refnum C.int) {
+	cb := _seq.FromRefNum(int32(refnum)).Get().(func())
+	cb()
+}
+
+`
+
+// genNSErrorCapture emits the C statements that snapshot an NSError
+// (bound to the local variable named by errVar) into the nserror struct
+// _<errVar>, so the domain, code and userInfo survive the trip to Go
+// instead of collapsing to an opaque message string.
+func (g *ObjcWrapper) genNSErrorCapture(errVar string) {
+	g.Printf("nserror _%s = {0};\n", errVar)
+	g.Printf("if (%s != nil) {\n", errVar)
+	g.Indent()
+	g.Printf("_%s.domain = go_seq_from_objc_string(%s.domain);\n", errVar, errVar)
+	g.Printf("_%s.code = (long)%s.code;\n", errVar, errVar)
+	g.Printf("_%s.userInfo = go_seq_from_objc_bytearray(go_seq_nserror_userinfo_snapshot(%s.userInfo), 1);\n", errVar, errVar)
+	g.Outdent()
+	g.Printf("}\n")
+}
+
+// msgSendStretDispatch picks the calling convention for methods returning a
+// struct: objc_msgSend_stret (and its super variant) on the legacy 32-bit
+// ABIs, where struct returns are passed via a hidden pointer argument, and
+// the regular objc_msgSend everywhere else.
+const msgSendStretDispatch = `#if defined(__i386__) || defined(__arm__)
+#define gomobile_msgSend_stret objc_msgSend_stret
+#define gomobile_msgSendSuper_stret objc_msgSendSuper_stret
+#else
+#define gomobile_msgSend_stret objc_msgSend
+#define gomobile_msgSendSuper_stret objc_msgSendSuper
+#endif
+
+`
+
+// kvoObserverClass is the shared KVO delegate every Observe<Property>
+// registration uses. Its fire function pointer is set per-registration by
+// cproxy_kvo_observe_%s_%s: a typed conversion function when the property's
+// value can be unboxed (see isKVOValueSupported), or left nil to fall back
+// to the untyped "something changed, re-read the getter" signal.
+const kvoObserverClass = `extern void cproxy_kvo_fire(int refnum);
+
+@interface GoSeqKVOObserver : NSObject
+@property (nonatomic) int refnum;
+@property (nonatomic) void (*fire)(int refnum, id oldValue, id newValue);
+@end
+
+@implementation GoSeqKVOObserver
+- (void)observeValueForKeyPath:(NSString *)keyPath ofObject:(id)object change:(NSDictionary *)change context:(void *)context {
+	if (self.fire != NULL) {
+		self.fire(self.refnum, change[NSKeyValueChangeOldKey], change[NSKeyValueChangeNewKey]);
+	} else {
+		cproxy_kvo_fire(self.refnum);
+	}
+}
+@end
+
+`
+
+func (g *ObjcWrapper) genKVOFuncs(n *objc.Named, p *objc.Property) {
+	typed := g.isKVOValueSupported(p.Type)
+	if typed {
+		ct := g.cType(p.Type)
+		g.Printf("extern void cproxy_kvo_fire_%s_%s(int refnum, %s old, %s new);\n", n.GoName, p.GoName, ct, ct)
+		g.Printf("static void cproxy_kvo_convert_%s_%s(int refnum, id oldValue, id newValue) {\n", n.GoName, p.GoName)
+		g.Indent()
+		g.genKVOUnbox("_old", "oldValue", p.Type)
+		g.genKVOUnbox("_new", "newValue", p.Type)
+		g.Printf("cproxy_kvo_fire_%s_%s(refnum, _old, _new);\n", n.GoName, p.GoName)
+		g.Outdent()
+		g.Printf("}\n")
+	}
+	g.Printf("int cproxy_kvo_observe_%s_%s(int this, int cb) {\n", n.GoName, p.GoName)
+	g.Indent()
+	g.Printf("id _this = go_seq_from_refnum(this).obj;\n")
+	g.Printf("GoSeqKVOObserver *obs = [[GoSeqKVOObserver alloc] init];\n")
+	g.Printf("obs.refnum = cb;\n")
+	if typed {
+		g.Printf("obs.fire = cproxy_kvo_convert_%s_%s;\n", n.GoName, p.GoName)
+		g.Printf("[_this addObserver:obs forKeyPath:@%q options:(NSKeyValueObservingOptionOld | NSKeyValueObservingOptionNew) context:NULL];\n", p.ObjCName)
+	} else {
+		g.Printf("[_this addObserver:obs forKeyPath:@%q options:0 context:NULL];\n", p.ObjCName)
+	}
+	g.Printf("return go_seq_to_refnum(obs);\n")
+	g.Outdent()
+	g.Printf("}\n")
+	g.Printf("void cproxy_kvo_unobserve_%s_%s(int this, int token) {\n", n.GoName, p.GoName)
+	g.Indent()
+	g.Printf("id _this = go_seq_from_refnum(this).obj;\n")
+	g.Printf("GoSeqKVOObserver *obs = go_seq_from_refnum(token).obj;\n")
+	g.Printf("[_this removeObserver:obs forKeyPath:@%q];\n", p.ObjCName)
+	g.Outdent()
+	g.Printf("}\n")
+}
+
+// genKVOUnbox converts the id variable named from - straight from a KVO
+// change dictionary, so an object property arrives as-is but a scalar one
+// arrives boxed in an NSNumber - into a local C variable named to, typed as
+// cType(t). An NSNull (the dictionary's nil stand-in) reads back as the
+// zero value.
+func (g *ObjcWrapper) genKVOUnbox(to, from string, t *objc.Type) {
+	ct := g.cType(t)
+	g.Printf("id %s_v = (%s == (id)[NSNull null]) ? nil : %s;\n", to, from, from)
+	switch t.Kind {
+	case objc.String:
+		g.Printf("%s %s = go_seq_from_objc_string((NSString *)%s_v);\n", ct, to, to)
+	case objc.Protocol, objc.Class:
+		g.Printf("%s %s = go_seq_to_refnum(%s_v);\n", ct, to, to)
+	case objc.Bool:
+		g.Printf("%s %s = %s_v ? (char)([(NSNumber *)%s_v boolValue] ? 1 : 0) : 0;\n", ct, to, to, to)
+	case objc.Int:
+		g.Printf("%s %s = %s_v ? (long)[(NSNumber *)%s_v longValue] : 0;\n", ct, to, to, to)
+	case objc.Uint:
+		g.Printf("%s %s = %s_v ? (unsigned long)[(NSNumber *)%s_v unsignedLongValue] : 0;\n", ct, to, to, to)
+	case objc.Short:
+		g.Printf("%s %s = %s_v ? (short)[(NSNumber *)%s_v shortValue] : 0;\n", ct, to, to, to)
+	case objc.Ushort:
+		g.Printf("%s %s = %s_v ? (unsigned short)[(NSNumber *)%s_v unsignedShortValue] : 0;\n", ct, to, to, to)
+	case objc.Char:
+		g.Printf("%s %s = %s_v ? (char)[(NSNumber *)%s_v charValue] : 0;\n", ct, to, to, to)
+	case objc.Uchar:
+		g.Printf("%s %s = %s_v ? (unsigned char)[(NSNumber *)%s_v unsignedCharValue] : 0;\n", ct, to, to, to)
+	case objc.Float:
+		g.Printf("%s %s = %s_v ? (float)[(NSNumber *)%s_v floatValue] : 0;\n", ct, to, to, to)
+	case objc.Double:
+		g.Printf("%s %s = %s_v ? (double)[(NSNumber *)%s_v doubleValue] : 0;\n", ct, to, to, to)
+	default:
+		panic("kvo value not supported")
+	}
 }
 
 func (g *ObjcWrapper) genM(n *objc.Named) {
@@ -218,6 +562,26 @@ func (g *ObjcWrapper) genM(n *objc.Named) {
 	}
 }
 
+// genIsInstance emits the ObjC-side backing for n's Cast/TryCast, mirroring
+// ClassGen's go_seq_isinstanceof check. Unlike a Java jclass, an ObjC Class
+// is always resolvable by name with no cache to warm, so the check is a
+// plain isKindOfClass: against the refnum's underlying object.
+//
+// This only mirrors ClassGen's runtime downcast check, not the subsystem
+// around it: there is still no ObjC analogue of ClassGen itself (no class
+// model built from ObjC metadata, no per-class Go package generation, no
+// `import "ObjC/..."` path for a class Go wasn't already wrapping). Cast
+// and TryCast only narrow among types ObjcWrapper already knows about; see
+// this package's doc comment for the full shape of that gap.
+func (g *ObjcWrapper) genIsInstance(n *objc.Named) {
+	g.Printf("int cproxy_isinstance_%s(int this) {\n", n.GoName)
+	g.Indent()
+	g.Printf("id _this = go_seq_from_refnum(this).obj;\n")
+	g.Printf("return [_this isKindOfClass:[%s class]] ? 1 : 0;\n", n.Name)
+	g.Outdent()
+	g.Printf("}\n\n")
+}
+
 func (g *ObjcWrapper) GenH() {
 	g.Printf(gobindPreamble)
 	g.Printf("#include \"seq.h\"\n\n")
@@ -237,14 +601,36 @@ func (g *ObjcWrapper) GenH() {
 			g.Printf("#import %q\n", n+".objc.h")
 		}
 	}
+	g.Printf("typedef struct nserror {\n")
+	g.Printf("	nstring domain;\n")
+	g.Printf("	long code;\n")
+	g.Printf("	nbyteslice userInfo;\n")
+	g.Printf("} nserror;\n")
 	for _, tn := range []string{"int", "nstring", "nbyteslice", "long", "unsigned long", "short", "unsigned short", "bool", "char", "unsigned char", "float", "double"} {
 		sn := strings.Replace(tn, " ", "_", -1)
 		g.Printf("typedef struct ret_%s {\n", sn)
 		g.Printf("	%s res;\n", tn)
-		g.Printf("	int err;\n")
+		g.Printf("	nserror err;\n")
 		g.Printf("} ret_%s;\n", sn)
 	}
 	g.Printf("\n")
+	// C shim structs mirroring the ObjC struct return types seen below, so
+	// cgo can read out their fields without depending on the real framework
+	// layout (which may differ between the 32- and 64-bit ABIs).
+	for _, name := range g.structOrder {
+		t := g.structs[name]
+		cname := structCName(t)
+		g.Printf("typedef struct %s {\n", cname)
+		for _, fl := range t.Fields {
+			g.Printf("	%s %s;\n", g.cType(fl.Type), fl.Name)
+		}
+		g.Printf("} %s;\n", cname)
+		g.Printf("typedef struct ret_struct_%s {\n", cname)
+		g.Printf("	struct %s res;\n", cname)
+		g.Printf("	nserror err;\n")
+		g.Printf("} ret_struct_%s;\n", cname)
+	}
+	g.Printf("\n")
 	for _, n := range g.types {
 		for _, f := range n.AllMethods {
 			if !g.isFuncSupported(f) {
@@ -259,6 +645,14 @@ func (g *ObjcWrapper) GenH() {
 				g.Printf(";\n")
 			}
 		}
+		for _, p := range n.Properties {
+			if !g.isSupported(p.Type) {
+				continue
+			}
+			g.Printf("extern int cproxy_kvo_observe_%s_%s(int this, int cb);\n", n.GoName, p.GoName)
+			g.Printf("extern void cproxy_kvo_unobserve_%s_%s(int this, int token);\n", n.GoName, p.GoName)
+		}
+		g.Printf("extern int cproxy_isinstance_%s(int this);\n", n.GoName)
 	}
 	for _, cls := range g.types {
 		g.genH(cls)
@@ -288,7 +682,7 @@ func (g *ObjcWrapper) genCFuncDecl(prefix, name string, f *objc.Func) {
 	case ret != nil:
 		g.Printf(g.cType(ret))
 	case returnsErr:
-		g.Printf("int")
+		g.Printf("nserror")
 	default:
 		g.Printf("void")
 	}
@@ -319,7 +713,14 @@ func (g *ObjcWrapper) GenGo() {
 	g.Printf("// #include \"interfaces.h\"\n")
 	g.Printf("import \"C\"\n\n")
 	g.Printf("import \"ObjC\"\n")
+	g.Printf("import \"strings\"\n")
+	g.Printf("import \"strconv\"\n")
+	g.Printf("import \"encoding/base64\"\n")
 	g.Printf("import _seq \"github.com/baguettex/gomobile/bind/seq\"\n")
+	if len(g.types) > 0 {
+		g.Printf("import \"reflect\"\n")
+		g.Printf("import \"fmt\"\n")
+	}
 
 	for _, n := range g.types {
 		for _, f := range n.Funcs {
@@ -330,16 +731,78 @@ func (g *ObjcWrapper) GenGo() {
 			}
 		}
 	}
+	for _, imp := range g.goClsImports {
+		g.Printf("import %q\n", imp)
+	}
 	g.Printf("\n")
 	g.Printf("type proxy interface { Bind_proxy_refnum__() int32 }\n\n")
 	g.Printf("// Suppress unused package error\n\n")
 	g.Printf("var _ = _seq.FromRefNum\n")
 	g.Printf("const _ = ObjC.Dummy\n\n")
+	g.Printf(decodeNSErrorUserInfoFunc)
+	g.Printf(decodeNSErrorFunc)
+	for _, sig := range g.blockOrder {
+		g.genBlockTrampoline(sig, g.blocks[sig])
+	}
+	g.Printf(kvoFireFunc)
+	for _, n := range g.types {
+		for _, p := range n.Properties {
+			if !g.isSupported(p.Type) || !g.isKVOValueSupported(p.Type) {
+				continue
+			}
+			g.genKVOFireFunc(n, p)
+		}
+	}
 	for _, n := range g.types {
 		g.genGo(n)
 	}
 }
 
+// genKVOFireFunc emits the //export function cproxy_kvo_convert_%s_%s
+// calls once it has unboxed the KVO change dictionary's old/new values into
+// p's cgoType. It looks up the Go callback Observe%s registered under
+// refnum and invokes it with the now Go-typed old/new values.
+func (g *ObjcWrapper) genKVOFireFunc(n *objc.Named, p *objc.Property) {
+	ct := g.cgoType(p.Type)
+	gt := g.goType(p.Type, false)
+	g.Printf("//export cproxy_kvo_fire_%s_%s\n", n.GoName, p.GoName)
+	g.Printf("func cproxy_kvo_fire_%s_%s(refnum C.int, oldVal %s, newVal %s) {\n", n.GoName, p.GoName, ct, ct)
+	g.Indent()
+	g.Printf("cb := _seq.FromRefNum(int32(refnum)).Get().(func(old, new %s))\n", gt)
+	g.genRead("_old", "oldVal", p.Type)
+	g.genRead("_new", "newVal", p.Type)
+	g.Printf("cb(_old, _new)\n")
+	g.Outdent()
+	g.Printf("}\n\n")
+}
+
+// genBlockTrampoline emits the //export function ObjC invokes to call
+// back into a Go closure boxed under refnum. It is shared by every block
+// parameter with the same signature.
+func (g *ObjcWrapper) genBlockTrampoline(sig string, f *objc.Func) {
+	g.Printf("//export cblock_%s\n", sig)
+	g.Printf("func cblock_%s(refnum C.int", sig)
+	for i, p := range f.Params {
+		g.Printf(", a%d %s", i, g.cgoType(p.Type))
+	}
+	g.Printf(") {\n")
+	g.Indent()
+	var goParams []string
+	for _, p := range f.Params {
+		goParams = append(goParams, g.goType(p.Type, false))
+	}
+	g.Printf("fn := _seq.FromRefNum(int32(refnum)).Get().(func(%s))\n", strings.Join(goParams, ", "))
+	var args []string
+	for i, p := range f.Params {
+		to := fmt.Sprintf("_a%d", i)
+		g.genRead(to, fmt.Sprintf("a%d", i), p.Type)
+		args = append(args, to)
+	}
+	g.Printf("fn(%s)\n", strings.Join(args, ", "))
+	g.Outdent()
+	g.Printf("}\n\n")
+}
+
 func (g *ObjcWrapper) genGo(n *objc.Named) {
 	g.Printf("func init() {\n")
 	g.Indent()
@@ -351,6 +814,29 @@ func (g *ObjcWrapper) genGo(n *objc.Named) {
 		g.genFuncDecl(false, f)
 		g.genFuncBody(n, f, "cproxy")
 	}
+	g.Printf("%s.Cast = func(v interface{}) ObjC.%s {\n", n.GoName, n.Module+"_"+n.GoName)
+	g.Indent()
+	g.Printf("t := reflect.TypeOf((*proxy_class_%s)(nil))\n", n.GoName)
+	g.Printf("cv := reflect.ValueOf(v).Convert(t).Interface().(*proxy_class_%s)\n", n.GoName)
+	g.Printf("if C.cproxy_isinstance_%s(C.int(cv.Bind_proxy_refnum__())) != 1 {\n", n.GoName)
+	g.Printf("	panic(fmt.Errorf(\"%%T is not an instance of %%s\", v, %q))\n", n.Name)
+	g.Printf("}\n")
+	g.Printf("return cv\n")
+	g.Outdent()
+	g.Printf("}\n")
+	g.Printf("%s.TryCast = func(v interface{}) (ObjC.%s, bool) {\n", n.GoName, n.Module+"_"+n.GoName)
+	g.Indent()
+	g.Printf("if _, isProxy := v.(proxy); !isProxy {\n")
+	g.Printf("	return nil, false\n")
+	g.Printf("}\n")
+	g.Printf("t := reflect.TypeOf((*proxy_class_%s)(nil))\n", n.GoName)
+	g.Printf("cv := reflect.ValueOf(v).Convert(t).Interface().(*proxy_class_%s)\n", n.GoName)
+	g.Printf("if C.cproxy_isinstance_%s(C.int(cv.Bind_proxy_refnum__())) != 1 {\n", n.GoName)
+	g.Printf("	return nil, false\n")
+	g.Printf("}\n")
+	g.Printf("return cv, true\n")
+	g.Outdent()
+	g.Printf("}\n")
 	g.Outdent()
 	g.Printf("}\n\n")
 	g.Printf("type proxy_class_%s _seq.Ref\n\n", n.GoName)
@@ -363,6 +849,20 @@ func (g *ObjcWrapper) genGo(n *objc.Named) {
 		g.genFuncDecl(false, f)
 		g.genFuncBody(n, f, "cproxy")
 	}
+	for _, p := range n.Properties {
+		if !g.isSupported(p.Type) {
+			continue
+		}
+		g.genObserve(n, p)
+	}
+	if goName, ok := g.goClsMap[n.GoName]; ok {
+		g.Printf("func (p *proxy_class_%s) Unwrap() interface{} {\n", n.GoName)
+		g.Indent()
+		g.Printf("goRefnum := C.go_seq_unwrap(C.int(p.Bind_proxy_refnum__()))\n")
+		g.Printf("return _seq.FromRefNum(int32(goRefnum)).Get().(*%s)\n", goName)
+		g.Outdent()
+		g.Printf("}\n\n")
+	}
 	if _, exists := g.supers[n.GoName]; exists {
 		g.Printf("func (p *proxy_class_%s) Super() ObjC.%s {\n", n.GoName, n.Module+"_"+n.GoName)
 		g.Printf("  return &super_%s{p}\n", n.GoName)
@@ -379,6 +879,52 @@ func (g *ObjcWrapper) genGo(n *objc.Named) {
 	}
 }
 
+// genObserve emits the Go proxy method that registers a KVO observer for
+// property p and returns a func to unregister it again. When p's type is
+// one genKVOFuncs knows how to unbox (see isKVOValueSupported), cb receives
+// the old and new values straight from the KVO change dictionary; otherwise
+// it falls back to a plain "something changed, re-read the getter" signal.
+func (g *ObjcWrapper) genObserve(n *objc.Named, p *objc.Property) {
+	g.Printf("func (p *proxy_class_%s) Observe%s(cb %s) func() {\n", n.GoName, p.GoName, g.observeCbType(p.Type, false))
+	g.Indent()
+	g.Printf("ref := C.int(_seq.ToRefNum(cb))\n")
+	g.Printf("token := C.cproxy_kvo_observe_%s_%s(C.int(p.Bind_proxy_refnum__()), ref)\n", n.GoName, p.GoName)
+	g.Printf("return func() {\n")
+	g.Printf("	C.cproxy_kvo_unobserve_%s_%s(C.int(p.Bind_proxy_refnum__()), token)\n", n.GoName, p.GoName)
+	g.Printf("}\n")
+	g.Outdent()
+	g.Printf("}\n\n")
+	if g.isKVOValueSupported(p.Type) {
+		g.genPropertyAccessor(n, p)
+	}
+}
+
+// genPropertyAccessor emits the <Prop>Property() method backing the
+// request for a Property[T]-style Get/Set/Observe accessor. Set is left
+// nil (and panics if called) when no setter method was generated for this
+// property - the only signal available to this generator that a property
+// is readonly, since the importer doesn't capture @property attributes.
+func (g *ObjcWrapper) genPropertyAccessor(n *objc.Named, p *objc.Property) {
+	setter := "Set" + p.GoName
+	hasSetter := false
+	for _, f := range n.AllMethods {
+		if f.GoName == setter {
+			hasSetter = true
+			break
+		}
+	}
+	gt := g.goType(p.Type, false)
+	g.Printf("func (p *proxy_class_%s) %sProperty() ObjC.Property[%s] {\n", n.GoName, p.GoName, gt)
+	g.Indent()
+	if hasSetter {
+		g.Printf("return ObjC.NewProperty[%s](p.%s, p.%s, p.Observe%s)\n", gt, p.GoName, setter, p.GoName)
+	} else {
+		g.Printf("return ObjC.NewProperty[%s](p.%s, nil, p.Observe%s)\n", gt, p.GoName, p.GoName)
+	}
+	g.Outdent()
+	g.Printf("}\n\n")
+}
+
 func (g *ObjcWrapper) genFuncBody(n *objc.Named, f *objc.Func, prefix string) {
 	g.Printf(" {\n")
 	g.Indent()
@@ -419,13 +965,13 @@ func (g *ObjcWrapper) genFuncBody(n *objc.Named, f *objc.Func, prefix string) {
 	switch {
 	case ret != nil && errParam != nil:
 		g.genRead("_res", "res.res", ret)
-		g.genRefRead("_"+errParam.Name, "res.err", "error", "proxy_error")
+		g.genNSErrorRead("_"+errParam.Name, "res.err")
 		g.Printf("return _res, _%s\n", errParam.Name)
 	case ret != nil:
 		g.genRead("_res", "res", ret)
 		g.Printf("return _res\n")
 	case errParam != nil:
-		g.genRefRead("_"+errParam.Name, "res", "error", "proxy_error")
+		g.genNSErrorRead("_"+errParam.Name, "res")
 		g.Printf("return _%s\n", errParam.Name)
 	}
 	g.Outdent()
@@ -448,11 +994,50 @@ func (g *ObjcWrapper) genCToObjC(name string, t *objc.Type, mode varMode) {
 		g.Printf("if (%s_ref != NULL) {\n", name)
 		g.Printf("	_%s = %s_ref.obj;\n", name, name)
 		g.Printf("}\n")
+	case objc.Block:
+		g.genBlockToObjC(name, t.Block, mode)
 	default:
 		panic("invalid kind")
 	}
 }
 
+// genBlockToObjC wraps the Go closure boxed in the refnum variable name
+// as an ObjC block literal _name. Invoking the block marshals its
+// arguments and calls back into Go through the trampoline shared by every
+// block with this signature (see blockSig/collectBlock).
+//
+// The block literal always starts life on the stack, so it is
+// _Block_copy'd onto the heap before it's safe to pass to objc_msgSend.
+// For modeTransient, the caller (genCFuncBody) balances that copy with a
+// _Block_release immediately after the call returns, since the block
+// isn't used once the method call completes. For modeRetained the block
+// is expected to escape (e.g. stored by the callee), so ownership of the
+// extra retain passes to whoever holds onto it; this generator does not
+// emit a matching release for that case.
+func (g *ObjcWrapper) genBlockToObjC(name string, f *objc.Func, mode varMode) {
+	sig := blockSig(f)
+	var objcParams []string
+	for i, p := range f.Params {
+		objcParams = append(objcParams, fmt.Sprintf("%s %s_a%d", g.objcType(p.Type), name, i))
+	}
+	g.Printf("void (^_%s)(%s) = nil;\n", name, strings.Join(objcParams, ", "))
+	g.Printf("if (%s != 0) {\n", name)
+	g.Indent()
+	g.Printf("_%s = _Block_copy(^(%s) {\n", name, strings.Join(objcParams, ", "))
+	g.Indent()
+	var args []string
+	for i, p := range f.Params {
+		argName := fmt.Sprintf("%s_a%d", name, i)
+		g.genObjCToC(argName, p.Type, modeTransient)
+		args = append(args, "_"+argName)
+	}
+	g.Printf("cblock_%s(%s);\n", sig, strings.Join(append([]string{name}, args...), ", "))
+	g.Outdent()
+	g.Printf("});\n")
+	g.Outdent()
+	g.Printf("}\n")
+}
+
 func (g *ObjcWrapper) genObjCToC(name string, t *objc.Type, mode varMode) {
 	switch t.Kind {
 	case objc.String:
@@ -463,6 +1048,11 @@ func (g *ObjcWrapper) genObjCToC(name string, t *objc.Type, mode varMode) {
 		g.Printf("%s _%s = (%s)%s;\n", g.cType(t), name, g.cType(t), name)
 	case objc.Protocol, objc.Class:
 		g.Printf("int _%s = go_seq_to_refnum(%s);\n", name, name)
+	case objc.Struct:
+		g.Printf("%s _%s;\n", g.cType(t), name)
+		for _, fl := range t.Fields {
+			g.Printf("_%s.%s = %s.%s;\n", name, fl.Name, name, fl.Name)
+		}
 	default:
 		panic("invalid kind")
 	}
@@ -481,7 +1071,7 @@ func (g *ObjcWrapper) genWrite(a *objc.Param) {
 		g.Printf("}\n")
 	case objc.Int, objc.Uint, objc.Short, objc.Ushort, objc.Char, objc.Uchar, objc.Float, objc.Double:
 		g.Printf("_%s := %s(%s)\n", a.Name, g.cgoType(a.Type), a.Name)
-	case objc.Protocol, objc.Class:
+	case objc.Protocol, objc.Class, objc.Block:
 		g.Printf("var _%s %s = _seq.NullRefNum\n", a.Name, g.cgoType(a.Type))
 		g.Printf("if %s != nil {\n", a.Name)
 		g.Printf("  _%s = %s(_seq.ToRefNum(%s))\n", a.Name, g.cgoType(a.Type), a.Name)
@@ -507,11 +1097,35 @@ func (g *ObjcWrapper) genRead(to, from string, t *objc.Type) {
 			proxyName = "proxy_class_" + n.GoName
 		}
 		g.genRefRead(to, from, g.goType(t, false), proxyName)
+	case objc.Struct:
+		g.Printf("%s := %s{\n", to, g.goType(t, false))
+		g.Indent()
+		for _, fl := range t.Fields {
+			g.Printf("%s: %s(%s.%s),\n", initialUpperObjC(fl.Name), g.goType(fl.Type, false), from, fl.Name)
+		}
+		g.Outdent()
+		g.Printf("}\n")
 	default:
 		panic("invalid kind")
 	}
 }
 
+// initialUpperObjC upper-cases the first rune of an ObjC struct field name
+// (e.g. "width" -> "Width") so it can be exported on the mirrored Go struct.
+func initialUpperObjC(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// genNSErrorRead reads a C.nserror value named from into a Go "error"
+// variable named to, producing a nil interface when the error is empty
+// and an *ObjC.NSError carrying the domain/code/userInfo otherwise.
+func (g *ObjcWrapper) genNSErrorRead(to, from string) {
+	g.Printf("%s := decodeNSError(%s)\n", to, from)
+}
+
 func (g *ObjcWrapper) genRefRead(to, from string, intfName, proxyName string) {
 	g.Printf("var %s %s\n", to, intfName)
 	g.Printf("%s_ref := _seq.FromRefNum(int32(%s))\n", to, from)
@@ -552,6 +1166,14 @@ func (g *ObjcWrapper) GenPackage(idx int) {
 			g.genFuncDecl(false, f)
 			g.Printf("\n")
 		}
+		g.Printf("// Cast takes a proxy for an ObjC object and converts it to a %s proxy.\n", n.GoName)
+		g.Printf("// Cast panics if the argument is not a proxy or if the underlying object does\n")
+		g.Printf("// not extend or implement %s.\n", n.Name)
+		g.Printf("Cast func(v interface{}) ObjC.%s\n", n.Module+"_"+n.GoName)
+		g.Printf("// TryCast is like Cast, but reports false instead of panicking when v\n")
+		g.Printf("// is not a proxy or the underlying object does not extend or implement\n")
+		g.Printf("// %s.\n", n.Name)
+		g.Printf("TryCast func(v interface{}) (ObjC.%s, bool)\n", n.Module+"_"+n.GoName)
 		g.Outdent()
 		g.Printf(")\n\n")
 	}
@@ -560,13 +1182,87 @@ func (g *ObjcWrapper) GenPackage(idx int) {
 func (g *ObjcWrapper) GenInterfaces() {
 	g.Printf(gobindPreamble)
 	g.Printf("package ObjC\n\n")
+	g.Printf("import \"fmt\"\n\n")
 	g.Printf("// Used to silence this package not used errors\n")
 	g.Printf("const Dummy = 0\n\n")
+	g.Printf(nsErrorType)
+	g.Printf(propertyType)
+	for _, name := range g.structOrder {
+		g.genStruct(g.structs[name])
+	}
 	for _, n := range g.types {
 		g.genInterface(n)
 	}
 }
 
+// genStruct emits the Go struct mirroring an objc.Struct type, e.g.
+//
+//	type CoreGraphics_CGRect struct {
+//		Origin CoreGraphics_CGPoint
+//		Size   CoreGraphics_CGSize
+//	}
+func (g *ObjcWrapper) genStruct(t *objc.Type) {
+	g.Printf("type %s struct {\n", t.Module+"_"+t.Name)
+	g.Indent()
+	for _, fl := range t.Fields {
+		g.Printf("%s %s\n", initialUpperObjC(fl.Name), g.goType(fl.Type, true))
+	}
+	g.Outdent()
+	g.Printf("}\n\n")
+}
+
+// nsErrorType is the Go type backing every NSError crossing the ObjC<->Go
+// bridge. It preserves domain, code and a snapshot of userInfo instead of
+// collapsing the error to its description string.
+const nsErrorType = `// NSError mirrors an Objective-C NSError, preserving its domain, code and
+// a snapshot of its userInfo rather than collapsing it to a bare message.
+type NSError struct {
+	Domain   string
+	Code     int
+	UserInfo map[string]interface{}
+	// Underlying holds the UserInfo["NSUnderlyingError"] leaf, if present.
+	Underlying error
+}
+
+func (e *NSError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Domain, e.Code)
+}
+
+`
+
+// propertyType backs every generated <Prop>Property() accessor: a thin
+// Get/Set/Observe wrapper over the property's existing getter, setter and
+// Observe<Prop> methods, so callers get a single handle instead of three
+// separately-named methods. Set panics on a readonly property (set == nil)
+// rather than silently doing nothing, since there is no setter to forward
+// to.
+const propertyType = `// Property is a Get/Set/Observe handle on a single Objective-C @property,
+// returned by a type's <Prop>Property() method.
+type Property[T any] struct {
+	get     func() T
+	set     func(T)
+	observe func(func(old, new T)) func()
+}
+
+// NewProperty builds a Property from a property's generated getter, setter
+// and Observe method. set may be nil for a readonly property.
+func NewProperty[T any](get func() T, set func(T), observe func(func(old, new T)) func()) Property[T] {
+	return Property[T]{get: get, set: set, observe: observe}
+}
+
+func (p Property[T]) Get() T { return p.get() }
+
+func (p Property[T]) Set(v T) {
+	if p.set == nil {
+		panic("Property.Set called on a readonly property")
+	}
+	p.set(v)
+}
+
+func (p Property[T]) Observe(cb func(old, new T)) func() { return p.observe(cb) }
+
+`
+
 func (g *ObjcWrapper) genInterface(n *objc.Named) {
 	g.Printf("type %s interface {\n", n.Module+"_"+n.GoName)
 	g.Indent()
@@ -579,9 +1275,25 @@ func (g *ObjcWrapper) genInterface(n *objc.Named) {
 		g.genFuncDecl(true, f)
 		g.Printf("\n")
 	}
+	for _, p := range n.Properties {
+		if !g.isSupported(p.Type) {
+			continue
+		}
+		g.Printf("Observe%s(cb %s) func()\n", p.GoName, g.observeCbType(p.Type, true))
+		if g.isKVOValueSupported(p.Type) {
+			g.Printf("%sProperty() Property[%s]\n", p.GoName, g.goType(p.Type, true))
+		}
+	}
 	if _, exists := g.supers[n.GoName]; exists {
 		g.Printf("Super() %s\n", n.Module+"_"+n.GoName)
 	}
+	if goName, ok := g.goClsMap[n.GoName]; ok {
+		g.Printf("// Unwrap returns the Go object this ObjC instance\n")
+		g.Printf("// is wrapping.\n")
+		g.Printf("// The return value is a %s, but the declared type is\n", goName)
+		g.Printf("// interface{} to avoid import cycles.\n")
+		g.Printf("Unwrap() interface{}\n")
+	}
 	g.Outdent()
 	g.Printf("}\n\n")
 }
@@ -619,15 +1331,27 @@ func (g *ObjcWrapper) genFuncDecl(local bool, f *objc.Func) {
 }
 
 func (g *ObjcWrapper) isFuncSupported(f *objc.Func) bool {
+	hasTrailingErr := len(f.Params) > 0 && g.isErrorType(f.Params[len(f.Params)-1].Type)
 	for i, p := range f.Params {
-		if !g.isSupported(p.Type) {
+		// Structs by value aren't marshaled as parameters yet, only as
+		// return values (via objc_msgSend_stret).
+		if !g.isSupported(p.Type) || p.Type.Kind == objc.Struct {
 			if i < len(f.Params)-1 || !g.isErrorType(p.Type) {
 				return false
 			}
 		}
 	}
 	if f.Ret != nil {
-		return g.isSupported(f.Ret)
+		if !g.isSupported(f.Ret) {
+			return false
+		}
+		// A struct return has no sentinel value the generated error check
+		// (genCFuncBody's "if (!res && ...)") can test for failure, so
+		// until that check grows a real out-of-band success flag, don't
+		// generate the struct+NSError** combination at all.
+		if f.Ret.Kind == objc.Struct && hasTrailingErr {
+			return false
+		}
 	}
 	return true
 }
@@ -637,6 +1361,33 @@ func (g *ObjcWrapper) isErrorType(t *objc.Type) bool {
 	return t.Kind == objc.Class && t.Indirect && t.Name == "NSError"
 }
 
+// isKVOValueSupported reports whether a KVO-observed old/new value of type
+// t can be unboxed from the NSDictionary change value (a plain object, or a
+// scalar boxed in an NSNumber by the KVO machinery itself) into a concrete
+// Go value. Data, Struct and Block properties are excluded: NSData/NSValue
+// unboxing for them isn't implemented, so Observe falls back to its plain
+// func() signature for those instead.
+func (g *ObjcWrapper) isKVOValueSupported(t *objc.Type) bool {
+	switch t.Kind {
+	case objc.String, objc.Protocol, objc.Class, objc.Bool,
+		objc.Int, objc.Uint, objc.Short, objc.Ushort,
+		objc.Char, objc.Uchar, objc.Float, objc.Double:
+		return true
+	default:
+		return false
+	}
+}
+
+// observeCbType is the Go type of the callback Observe<Prop> takes: typed
+// old/new values when isKVOValueSupported(t), otherwise the original
+// zero-argument "something changed, re-read the getter" signature.
+func (g *ObjcWrapper) observeCbType(t *objc.Type, local bool) string {
+	if !g.isKVOValueSupported(t) {
+		return "func()"
+	}
+	return fmt.Sprintf("func(old, new %s)", g.goType(t, local))
+}
+
 func (g *ObjcWrapper) isSupported(t *objc.Type) bool {
 	if t.Indirect {
 		return false
@@ -649,6 +1400,18 @@ func (g *ObjcWrapper) isSupported(t *objc.Type) bool {
 		return !strings.HasSuffix(t.Decl, " *")
 	case objc.Class:
 		return t.Name != "SEL" && t.Name != "void"
+	case objc.Block:
+		// Only void-returning blocks are supported so far; scalar/NSError
+		// returning blocks are a follow-up.
+		if t.Block.Ret != nil {
+			return false
+		}
+		for _, p := range t.Block.Params {
+			if !g.isSupported(p.Type) {
+				return false
+			}
+		}
+		return true
 	default:
 		return true
 	}
@@ -669,7 +1432,7 @@ func (g *ObjcWrapper) cgoType(t *objc.Type) string {
 
 func (g *ObjcWrapper) cType(t *objc.Type) string {
 	switch t.Kind {
-	case objc.Protocol, objc.Class:
+	case objc.Protocol, objc.Class, objc.Block:
 		return "int"
 	case objc.String:
 		return "nstring"
@@ -693,11 +1456,19 @@ func (g *ObjcWrapper) cType(t *objc.Type) string {
 		return "float"
 	case objc.Double:
 		return "double"
+	case objc.Struct:
+		return "struct " + structCName(t)
 	default:
 		panic("invalid kind")
 	}
 }
 
+// structCName is the name of the C shim struct mirroring an objc.Struct,
+// e.g. CoreGraphics_CGRect for CGRect.
+func structCName(t *objc.Type) string {
+	return strings.Replace(t.Module+"_"+t.Name, " ", "_", -1)
+}
+
 func (g *ObjcWrapper) objcType(t *objc.Type) string {
 	return t.Decl
 }
@@ -750,6 +1521,18 @@ func (g *ObjcWrapper) goType(t *objc.Type, local bool) string {
 			name = "ObjC." + name
 		}
 		return name
+	case objc.Struct:
+		name := t.Module + "_" + t.Name
+		if !local {
+			name = "ObjC." + name
+		}
+		return name
+	case objc.Block:
+		var params []string
+		for _, p := range t.Block.Params {
+			params = append(params, g.goType(p.Type, local))
+		}
+		return "func(" + strings.Join(params, ", ") + ")"
 	default:
 		panic("invalid kind")
 	}